@@ -0,0 +1,305 @@
+// Package cidrmath implements the prefix arithmetic behind netcalc's
+// subnetting subcommands: carving a prefix into child subnets, addressing
+// individual hosts within a prefix, and collapsing adjacent prefixes back
+// into their smallest covering aggregates. Every function works on both
+// IPv4 and IPv6 prefixes and uses math/big internally so large IPv6 ranges
+// never overflow a machine word.
+package cidrmath
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"sort"
+)
+
+var (
+	// ErrNewBitsOutOfRange is returned when newBits would extend a prefix
+	// past the address width (/32 for IPv4, /128 for IPv6) or isn't positive.
+	ErrNewBitsOutOfRange = errors.New("cidrmath: newBits out of range")
+	// ErrNetNumOutOfRange is returned when netNum addresses a subnet that
+	// doesn't exist for the requested newBits.
+	ErrNetNumOutOfRange = errors.New("cidrmath: netNum out of range")
+	// ErrHostNumOutOfRange is returned when hostNum addresses a host that
+	// doesn't exist in the prefix.
+	ErrHostNumOutOfRange = errors.New("cidrmath: hostNum out of range")
+	// ErrSubnetsOverflow is returned when a sequential allocation of
+	// variable-sized subnets no longer fits in the parent prefix.
+	ErrSubnetsOverflow = errors.New("cidrmath: subnets do not fit in parent prefix")
+	// ErrFamilyMismatch is returned when Join is asked to collapse a mix of
+	// IPv4 and IPv6 prefixes.
+	ErrFamilyMismatch = errors.New("cidrmath: mismatched address families")
+)
+
+// Subnet extends prefix by newBits and returns the netNum-th child subnet,
+// e.g. Subnet("10.0.0.0/8", 8, 4) is "10.4.0.0/16".
+func Subnet(prefix netip.Prefix, newBits, netNum int) (netip.Prefix, error) {
+	totalBits := addrBits(prefix.Addr())
+	childBits := prefix.Bits() + newBits
+	if newBits <= 0 || childBits > totalBits {
+		return netip.Prefix{}, fmt.Errorf("%w: newBits %d extends /%d past /%d", ErrNewBitsOutOfRange, newBits, prefix.Bits(), totalBits)
+	}
+
+	maxNet := new(big.Int).Lsh(big.NewInt(1), uint(newBits))
+	if netNum < 0 || big.NewInt(int64(netNum)).Cmp(maxNet) >= 0 {
+		return netip.Prefix{}, fmt.Errorf("%w: netNum %d out of range for newBits %d (0-%s)", ErrNetNumOutOfRange, netNum, newBits, new(big.Int).Sub(maxNet, big.NewInt(1)))
+	}
+
+	base := new(big.Int).SetBytes(prefix.Masked().Addr().AsSlice())
+	offset := new(big.Int).Lsh(big.NewInt(int64(netNum)), uint(totalBits-childBits))
+	base.Add(base, offset)
+
+	addr, err := addrFromBigInt(base, totalBits, prefix.Addr().Is4())
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, childBits), nil
+}
+
+// Host returns the hostNum-th address inside prefix. A negative hostNum
+// counts from the end of the prefix, so Host(p, -1) is the last address.
+func Host(prefix netip.Prefix, hostNum int64) (netip.Addr, error) {
+	totalBits := addrBits(prefix.Addr())
+	hostBits := totalBits - prefix.Bits()
+	count := new(big.Int).Lsh(big.NewInt(1), uint(hostBits))
+
+	offset := big.NewInt(hostNum)
+	if hostNum < 0 {
+		offset = new(big.Int).Add(count, offset)
+	}
+	if offset.Sign() < 0 || offset.Cmp(count) >= 0 {
+		return netip.Addr{}, fmt.Errorf("%w: hostNum %d out of range for /%d", ErrHostNumOutOfRange, hostNum, prefix.Bits())
+	}
+
+	base := new(big.Int).SetBytes(prefix.Masked().Addr().AsSlice())
+	base.Add(base, offset)
+	return addrFromBigInt(base, totalBits, prefix.Addr().Is4())
+}
+
+// Subnets allocates variable-sized child subnets out of prefix sequentially,
+// one per entry in newBits, rounding each allocation up to its own natural
+// alignment so none of the returned subnets overlap.
+func Subnets(prefix netip.Prefix, newBits ...int) ([]netip.Prefix, error) {
+	totalBits := addrBits(prefix.Addr())
+	is4 := prefix.Addr().Is4()
+	base := new(big.Int).SetBytes(prefix.Masked().Addr().AsSlice())
+	parentSize := new(big.Int).Lsh(big.NewInt(1), uint(totalBits-prefix.Bits()))
+	parentEnd := new(big.Int).Add(base, parentSize)
+
+	cursor := new(big.Int).Set(base)
+	out := make([]netip.Prefix, 0, len(newBits))
+
+	for _, nb := range newBits {
+		childBits := prefix.Bits() + nb
+		if nb <= 0 || childBits > totalBits {
+			return nil, fmt.Errorf("%w: newBits %d extends /%d past /%d", ErrNewBitsOutOfRange, nb, prefix.Bits(), totalBits)
+		}
+		size := new(big.Int).Lsh(big.NewInt(1), uint(totalBits-childBits))
+
+		if rem := new(big.Int).Mod(cursor, size); rem.Sign() != 0 {
+			cursor.Add(cursor, new(big.Int).Sub(size, rem))
+		}
+
+		end := new(big.Int).Add(cursor, size)
+		if end.Cmp(parentEnd) > 0 {
+			return nil, fmt.Errorf("%w: %s needs %d more bits than %s has left", ErrSubnetsOverflow, prefix, nb, prefix)
+		}
+
+		addr, err := addrFromBigInt(cursor, totalBits, is4)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, netip.PrefixFrom(addr, childBits))
+		cursor = end
+	}
+	return out, nil
+}
+
+// Split divides prefix into n equal-size subnets, rounding n up to the next
+// power of two.
+func Split(prefix netip.Prefix, n int) ([]netip.Prefix, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("%w: split count %d must be positive", ErrNetNumOutOfRange, n)
+	}
+
+	newBits := 0
+	for (1 << uint(newBits)) < n {
+		newBits++
+	}
+
+	list := make([]int, 1<<uint(newBits))
+	for i := range list {
+		list[i] = newBits
+	}
+	return Subnets(prefix, list...)
+}
+
+// Join collapses prefixes into the smallest set of aggregates by repeatedly
+// merging sibling pairs that share a parent. Prefixes must all be the same
+// address family; the result is sorted and deduplicated.
+func Join(prefixes ...netip.Prefix) ([]netip.Prefix, error) {
+	if len(prefixes) == 0 {
+		return nil, nil
+	}
+
+	is4 := prefixes[0].Addr().Is4()
+	totalBits := addrBits(prefixes[0].Addr())
+	merged := make([]netip.Prefix, len(prefixes))
+	for i, p := range prefixes {
+		if p.Addr().Is4() != is4 {
+			return nil, fmt.Errorf("%w: %s is not the same family as %s", ErrFamilyMismatch, p, prefixes[0])
+		}
+		merged[i] = p.Masked()
+	}
+
+	for {
+		sort.Slice(merged, func(i, j int) bool {
+			ai := new(big.Int).SetBytes(merged[i].Addr().AsSlice())
+			aj := new(big.Int).SetBytes(merged[j].Addr().AsSlice())
+			if c := ai.Cmp(aj); c != 0 {
+				return c < 0
+			}
+			return merged[i].Bits() < merged[j].Bits()
+		})
+		merged = dedupeContained(merged)
+
+		next := merged[:0:0]
+		changed := false
+		for i := 0; i < len(merged); i++ {
+			if i+1 < len(merged) {
+				if parent, ok := mergeSiblings(merged[i], merged[i+1], totalBits); ok {
+					next = append(next, parent)
+					i++
+					changed = true
+					continue
+				}
+			}
+			next = append(next, merged[i])
+		}
+		merged = next
+		if !changed {
+			break
+		}
+	}
+	return merged, nil
+}
+
+// Aggregate computes the minimal covering set for a mixed-family list of
+// prefixes, grouped into separate IPv4 and IPv6 results. Each family is
+// sorted once and then merged in a single left-to-right pass with a stack,
+// so the whole operation is O(n log n), dominated by the sort.
+func Aggregate(prefixes []netip.Prefix) (ipv4, ipv6 []netip.Prefix) {
+	var v4in, v6in []netip.Prefix
+	for _, p := range prefixes {
+		if p.Addr().Is4() {
+			v4in = append(v4in, p)
+		} else {
+			v6in = append(v6in, p)
+		}
+	}
+	return aggregateFamily(v4in), aggregateFamily(v6in)
+}
+
+func aggregateFamily(prefixes []netip.Prefix) []netip.Prefix {
+	if len(prefixes) == 0 {
+		return nil
+	}
+	totalBits := addrBits(prefixes[0].Addr())
+
+	sorted := make([]netip.Prefix, len(prefixes))
+	for i, p := range prefixes {
+		sorted[i] = p.Masked()
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		ai := new(big.Int).SetBytes(sorted[i].Addr().AsSlice())
+		aj := new(big.Int).SetBytes(sorted[j].Addr().AsSlice())
+		if c := ai.Cmp(aj); c != 0 {
+			return c < 0
+		}
+		return sorted[i].Bits() < sorted[j].Bits()
+	})
+
+	stack := make([]netip.Prefix, 0, len(sorted))
+	for _, p := range sorted {
+		if len(stack) > 0 && stack[len(stack)-1].Overlaps(p) && stack[len(stack)-1].Bits() <= p.Bits() {
+			continue // p is already covered by the prefix on top of the stack
+		}
+
+		stack = append(stack, p)
+		for len(stack) >= 2 {
+			parent, ok := mergeSiblings(stack[len(stack)-2], stack[len(stack)-1], totalBits)
+			if !ok {
+				break
+			}
+			stack = append(stack[:len(stack)-2], parent)
+		}
+	}
+	return stack
+}
+
+// mergeSiblings returns the shared parent of a and b when they are the two
+// halves of the same /n-1 prefix.
+func mergeSiblings(a, b netip.Prefix, totalBits int) (netip.Prefix, bool) {
+	if a.Bits() != b.Bits() || a.Bits() == 0 {
+		return netip.Prefix{}, false
+	}
+
+	parentBits := a.Bits() - 1
+	size := new(big.Int).Lsh(big.NewInt(1), uint(totalBits-a.Bits()))
+	aBase := new(big.Int).SetBytes(a.Addr().AsSlice())
+	bBase := new(big.Int).SetBytes(b.Addr().AsSlice())
+
+	if new(big.Int).Add(aBase, size).Cmp(bBase) != 0 {
+		return netip.Prefix{}, false
+	}
+	if new(big.Int).Mod(aBase, new(big.Int).Lsh(big.NewInt(1), uint(totalBits-parentBits))).Sign() != 0 {
+		return netip.Prefix{}, false
+	}
+
+	addr, err := addrFromBigInt(aBase, totalBits, a.Addr().Is4())
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	return netip.PrefixFrom(addr, parentBits), true
+}
+
+// dedupeContained drops prefixes already covered by an earlier, shorter
+// prefix in a sorted-by-address slice.
+func dedupeContained(sorted []netip.Prefix) []netip.Prefix {
+	out := sorted[:0:0]
+	for _, p := range sorted {
+		if len(out) > 0 && out[len(out)-1].Overlaps(p) && out[len(out)-1].Bits() <= p.Bits() {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+func addrBits(a netip.Addr) int {
+	if a.Is4() {
+		return 32
+	}
+	return 128
+}
+
+func addrFromBigInt(n *big.Int, totalBits int, is4 bool) (netip.Addr, error) {
+	byteLen := totalBits / 8
+	b := n.Bytes()
+	if len(b) > byteLen {
+		return netip.Addr{}, fmt.Errorf("cidrmath: address overflows %d bits", totalBits)
+	}
+
+	buf := make([]byte, byteLen)
+	copy(buf[byteLen-len(b):], b)
+
+	addr, ok := netip.AddrFromSlice(buf)
+	if !ok {
+		return netip.Addr{}, fmt.Errorf("cidrmath: invalid %d-byte address", byteLen)
+	}
+	if is4 {
+		addr = addr.Unmap()
+	}
+	return addr, nil
+}
@@ -0,0 +1,39 @@
+package cidrmath
+
+import (
+	"fmt"
+	"math/rand"
+	"net/netip"
+	"testing"
+)
+
+// benchPrefixes returns n pseudo-random, mostly non-adjacent IPv4 /24s
+// scattered across 10.0.0.0/8, the kind of input aggregation tools see in
+// practice: some merges, most not.
+func benchPrefixes(n int) []netip.Prefix {
+	r := rand.New(rand.NewSource(1))
+	prefixes := make([]netip.Prefix, n)
+	for i := range prefixes {
+		prefixes[i] = netip.MustParsePrefix(fmt.Sprintf("10.%d.%d.0/24", r.Intn(256), r.Intn(256)))
+	}
+	return prefixes
+}
+
+func BenchmarkAggregate(b *testing.B) {
+	prefixes := benchPrefixes(100_000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Aggregate(prefixes)
+	}
+}
+
+// BenchmarkJoin covers the same 100k-prefix input as BenchmarkAggregate so
+// the two can be compared directly: unlike Aggregate's single stack pass,
+// Join re-sorts and re-scans on every round that merges a pair.
+func BenchmarkJoin(b *testing.B) {
+	prefixes := benchPrefixes(100_000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Join(prefixes...)
+	}
+}
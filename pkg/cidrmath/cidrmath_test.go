@@ -0,0 +1,202 @@
+package cidrmath
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestSubnet(t *testing.T) {
+	cases := []struct {
+		name    string
+		prefix  string
+		newBits int
+		netNum  int
+		want    string
+		wantErr bool
+	}{
+		{"classic 10/8 -> /16 #4", "10.0.0.0/8", 8, 4, "10.4.0.0/16", false},
+		{"ipv6", "2001:db8::/32", 16, 5, "2001:db8:5::/48", false},
+		{"netNum zero", "10.0.0.0/8", 8, 0, "10.0.0.0/16", false},
+		{"last valid netNum", "10.0.0.0/8", 8, 255, "10.255.0.0/16", false},
+		{"newBits zero rejected", "10.0.0.0/8", 0, 0, "", true},
+		{"newBits extends past /32", "10.0.0.0/24", 9, 0, "", true},
+		{"netNum out of range", "10.0.0.0/8", 8, 256, "", true},
+		{"negative netNum", "10.0.0.0/8", 8, -1, "", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Subnet(netip.MustParsePrefix(tc.prefix), tc.newBits, tc.netNum)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("Subnet(%s, %d, %d) = %s, want error", tc.prefix, tc.newBits, tc.netNum, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Subnet(%s, %d, %d): unexpected error: %v", tc.prefix, tc.newBits, tc.netNum, err)
+			}
+			if got.String() != tc.want {
+				t.Errorf("Subnet(%s, %d, %d) = %s, want %s", tc.prefix, tc.newBits, tc.netNum, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHost(t *testing.T) {
+	cases := []struct {
+		name    string
+		prefix  string
+		hostNum int64
+		want    string
+		wantErr bool
+	}{
+		{"first host", "192.168.1.0/24", 0, "192.168.1.0", false},
+		{"last host via -1", "192.168.1.0/24", -1, "192.168.1.255", false},
+		{"second-to-last via -2", "192.168.1.0/24", -2, "192.168.1.254", false},
+		{"out of range positive", "192.168.1.0/24", 256, "", true},
+		{"out of range negative", "192.168.1.0/24", -257, "", true},
+		{"single host /32", "10.0.0.5/32", 0, "10.0.0.5", false},
+		{"single host /32 out of range", "10.0.0.5/32", 1, "", true},
+		{"ipv6", "2001:db8::/64", 1, "2001:db8::1", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Host(netip.MustParsePrefix(tc.prefix), tc.hostNum)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("Host(%s, %d) = %s, want error", tc.prefix, tc.hostNum, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Host(%s, %d): unexpected error: %v", tc.prefix, tc.hostNum, err)
+			}
+			if got.String() != tc.want {
+				t.Errorf("Host(%s, %d) = %s, want %s", tc.prefix, tc.hostNum, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSubnets(t *testing.T) {
+	cases := []struct {
+		name    string
+		prefix  string
+		newBits []int
+		want    []string
+		wantErr bool
+	}{
+		{
+			"variable sizes leave an alignment gap",
+			"10.0.0.0/24", []int{2, 2, 1},
+			[]string{"10.0.0.0/26", "10.0.0.64/26", "10.0.0.128/25"},
+			false,
+		},
+		{
+			"exact fill",
+			"10.0.0.0/24", []int{2, 2, 2, 2},
+			[]string{"10.0.0.0/26", "10.0.0.64/26", "10.0.0.128/26", "10.0.0.192/26"},
+			false,
+		},
+		{"zero newBits rejected", "10.0.0.0/24", []int{0}, nil, true},
+		{"does not fit in parent", "10.0.0.0/30", []int{1, 1, 1}, nil, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Subnets(netip.MustParsePrefix(tc.prefix), tc.newBits...)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("Subnets(%s, %v) = %v, want error", tc.prefix, tc.newBits, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Subnets(%s, %v): unexpected error: %v", tc.prefix, tc.newBits, err)
+			}
+			if !sameStrings(got, tc.want) {
+				t.Errorf("Subnets(%s, %v) = %v, want %v", tc.prefix, tc.newBits, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	cases := []struct {
+		name    string
+		prefix  string
+		n       int
+		want    []string
+		wantErr bool
+	}{
+		{"power of two", "10.0.0.0/24", 4, []string{"10.0.0.0/26", "10.0.0.64/26", "10.0.0.128/26", "10.0.0.192/26"}, false},
+		{"rounds up to next power of two", "10.0.0.0/24", 3, []string{"10.0.0.0/26", "10.0.0.64/26", "10.0.0.128/26", "10.0.0.192/26"}, false},
+		{"n not positive", "10.0.0.0/24", 0, nil, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Split(netip.MustParsePrefix(tc.prefix), tc.n)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("Split(%s, %d) = %v, want error", tc.prefix, tc.n, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Split(%s, %d): unexpected error: %v", tc.prefix, tc.n, err)
+			}
+			if !sameStrings(got, tc.want) {
+				t.Errorf("Split(%s, %d) = %v, want %v", tc.prefix, tc.n, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestJoin(t *testing.T) {
+	cases := []struct {
+		name    string
+		in      []string
+		want    []string
+		wantErr bool
+	}{
+		{"two siblings merge", []string{"10.0.0.0/25", "10.0.0.128/25"}, []string{"10.0.0.0/24"}, false},
+		{"cascading merge", []string{"10.0.0.0/25", "10.0.0.128/25", "10.0.1.0/24"}, []string{"10.0.0.0/23"}, false},
+		{"non-adjacent prefixes stay separate", []string{"10.0.0.0/24", "10.0.2.0/24"}, []string{"10.0.0.0/24", "10.0.2.0/24"}, false},
+		{"unordered input still merges", []string{"10.0.0.128/25", "10.0.0.0/25"}, []string{"10.0.0.0/24"}, false},
+		{"mismatched families error", []string{"10.0.0.0/24", "2001:db8::/32"}, nil, true},
+		{"empty input", nil, nil, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			prefixes := make([]netip.Prefix, len(tc.in))
+			for i, s := range tc.in {
+				prefixes[i] = netip.MustParsePrefix(s)
+			}
+
+			got, err := Join(prefixes...)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("Join(%v) = %v, want error", tc.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Join(%v): unexpected error: %v", tc.in, err)
+			}
+			if !sameStrings(got, tc.want) {
+				t.Errorf("Join(%v) = %v, want %v", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func sameStrings(prefixes []netip.Prefix, want []string) bool {
+	if len(prefixes) != len(want) {
+		return false
+	}
+	for i, p := range prefixes {
+		if p.String() != want[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+
+	"netcalc/pkg/cidrmath"
+)
+
+func runAggregateCmd(args []string) {
+	prefixes := readPrefixList(args)
+
+	ipv4, ipv6 := cidrmath.Aggregate(prefixes)
+
+	if len(ipv4) > 0 && len(ipv6) > 0 {
+		fmt.Println("# IPv4")
+	}
+	for _, p := range ipv4 {
+		fmt.Println(p)
+	}
+	if len(ipv4) > 0 && len(ipv6) > 0 {
+		fmt.Println("# IPv6")
+	}
+	for _, p := range ipv6 {
+		fmt.Println(p)
+	}
+}
+
+func runContainsCmd(args []string) {
+	if len(args) != 2 {
+		fatalSubnetUsage()
+	}
+
+	outer := mustParsePrefix(args[0])
+	contains := false
+
+	if addr, err := netip.ParseAddr(args[1]); err == nil {
+		contains = outer.Contains(addr)
+	} else {
+		inner := mustParsePrefix(args[1])
+		contains = outer.Bits() <= inner.Bits() && outer.Contains(inner.Addr())
+	}
+
+	fmt.Println(contains)
+	if !contains {
+		os.Exit(1)
+	}
+}
+
+func runOverlapsCmd(args []string) {
+	if len(args) != 2 {
+		fatalSubnetUsage()
+	}
+
+	a := mustParsePrefix(args[0])
+	b := mustParsePrefix(args[1])
+	overlaps := a.Overlaps(b)
+
+	fmt.Println(overlaps)
+	if !overlaps {
+		os.Exit(1)
+	}
+}
+
+// readPrefixList reads CIDRs from args if any are given, otherwise one per
+// line from stdin, matching the convention -batch uses for bulk input.
+func readPrefixList(args []string) []netip.Prefix {
+	if len(args) > 0 {
+		prefixes := make([]netip.Prefix, len(args))
+		for i, a := range args {
+			prefixes[i] = mustParsePrefix(a)
+		}
+		return prefixes
+	}
+
+	var prefixes []netip.Prefix
+	scanner := bufio.NewScanner(os.Stdin)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p, err := netip.ParsePrefix(line)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "line %d: %q: %v\n", lineNum, line, err)
+			continue
+		}
+		prefixes = append(prefixes, p.Masked())
+	}
+	if err := scanner.Err(); err != nil {
+		fatal(err)
+	}
+	return prefixes
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	batchMode    = flag.Bool("batch", false, "read one CIDR per line from stdin (or -i) and compute them concurrently")
+	batchInput   = flag.String("i", "", "input file for -batch (default: stdin)")
+	batchWorkers = flag.Int("workers", 0, "number of -batch workers (default: GOMAXPROCS)")
+)
+
+// batchJob is one input line paired with the line number it came from, so
+// errors can be reported against the original input.
+type batchJob struct {
+	lineNum int
+	cidr    string
+}
+
+// BatchResult is a Result with the input CIDR that produced it, so NDJSON
+// output is self-describing without relying on line order.
+type BatchResult struct {
+	Input string `json:"input" yaml:"input"`
+	Result
+}
+
+var batchHeader = []string{
+	"input", "family", "address", "bitmask", "netmask",
+	"wildcard", "network", "broadcast", "hostmin", "hostmax", "hosts",
+}
+
+// runBatchMode computes results for every CIDR read from stdin or -i using a
+// worker pool, streaming each result as it completes. It reports its own
+// errors to stderr (line-numbered) and returns a process exit code rather
+// than calling os.Exit itself, so callers can still flush output first.
+func runBatchMode() int {
+	if !isBatchFormat(*format) {
+		fmt.Fprintf(os.Stderr, "Error: unknown -batch format %q: want text, json, csv or tsv\n", *format)
+		return 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	defer signal.Stop(sigCh)
+	go func() {
+		select {
+		case <-sigCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	in := io.Reader(os.Stdin)
+	if *batchInput != "" {
+		f, err := os.Open(*batchInput)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return 1
+		}
+		defer f.Close()
+		in = f
+	}
+
+	workers := *batchWorkers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	var out sync.Mutex
+	if *format != "json" {
+		cw := csv.NewWriter(os.Stdout)
+		cw.Comma = batchDelim(*format)
+		cw.Write(batchHeader)
+		cw.Flush()
+	}
+
+	var failed int32
+	jobs := make(chan batchJob)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				if ctx.Err() != nil {
+					return
+				}
+				if err := processBatchJob(job, &out); err != nil {
+					fmt.Fprintf(os.Stderr, "line %d: %q: %v\n", job.lineNum, job.cidr, err)
+					atomic.AddInt32(&failed, 1)
+				}
+			}
+		}()
+	}
+
+	scanner := bufio.NewScanner(in)
+	lineNum := 0
+feed:
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			break feed
+		case jobs <- batchJob{lineNum: lineNum, cidr: line}:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		failed++
+	}
+
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func processBatchJob(job batchJob, out *sync.Mutex) error {
+	result, err := calculate(job.cidr)
+	if err != nil {
+		return err
+	}
+	result.ComputedAt = time.Now().UTC().Format(time.RFC3339)
+
+	out.Lock()
+	defer out.Unlock()
+
+	if *format == "json" {
+		return json.NewEncoder(os.Stdout).Encode(BatchResult{Input: job.cidr, Result: result})
+	}
+
+	cw := csv.NewWriter(os.Stdout)
+	cw.Comma = batchDelim(*format)
+	row := []string{
+		job.cidr, result.Family, result.Address, strconv.Itoa(result.Bitmask), result.Netmask,
+		result.Wildcard, result.Network, result.Broadcast, result.Hostmin, result.Hostmax, result.Hosts,
+	}
+	if err := cw.Write(row); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// batchDelim maps the -format flag to a table delimiter for batch mode; text
+// and csv both render as comma-separated, tsv as tab-separated.
+func batchDelim(format string) rune {
+	if format == "tsv" {
+		return '\t'
+	}
+	return ','
+}
+
+// isBatchFormat reports whether format is one -batch knows how to render.
+// Unlike single-CIDR mode, -batch has no yaml writer, so yaml is rejected
+// here even though writeResult accepts it.
+func isBatchFormat(format string) bool {
+	switch format {
+	case "text", "json", "csv", "tsv":
+		return true
+	default:
+		return false
+	}
+}
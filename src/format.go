@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// writeResult renders result to w in the requested format.
+func writeResult(w io.Writer, result Result, format string, pretty bool) error {
+	switch format {
+	case "text":
+		return writeText(w, result)
+	case "json":
+		return writeJSON(w, result, pretty)
+	case "yaml":
+		return writeYAML(w, result)
+	case "csv":
+		return writeDelimited(w, result, ',')
+	case "tsv":
+		return writeDelimited(w, result, '\t')
+	default:
+		return fmt.Errorf("unknown format %q: want text, json, yaml, csv or tsv", format)
+	}
+}
+
+func writeText(w io.Writer, result Result) error {
+	for _, item := range result.items() {
+		if _, err := fmt.Fprintf(w, "%-12s %s\n", item.Name+":", item.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeJSON(w io.Writer, result Result, pretty bool) error {
+	enc := json.NewEncoder(w)
+	if pretty {
+		enc.SetIndent("", "  ")
+	}
+	return enc.Encode(result)
+}
+
+// writeYAML emits a flat YAML mapping. Result has no nested fields, so a
+// hand-rolled encoder avoids pulling in a YAML library for ten key/value
+// lines. bitmask is written unquoted to stay a YAML integer, matching its
+// `int` type and keeping it consistent with the JSON encoding.
+func writeYAML(w io.Writer, result Result) error {
+	fields := []struct {
+		key     string
+		value   string
+		numeric bool
+	}{
+		{"family", result.Family, false},
+		{"address", result.Address, false},
+		{"bitmask", fmt.Sprintf("%d", result.Bitmask), true},
+		{"netmask", result.Netmask, false},
+		{"wildcard", result.Wildcard, false},
+		{"network", result.Network, false},
+		{"broadcast", result.Broadcast, false},
+		{"hostmin", result.Hostmin, false},
+		{"hostmax", result.Hostmax, false},
+		{"hosts", result.Hosts, false},
+		{"computed_at", result.ComputedAt, false},
+	}
+	for _, f := range fields {
+		format := "%s: %q\n"
+		if f.numeric {
+			format = "%s: %s\n"
+		}
+		if _, err := fmt.Fprintf(w, format, f.key, f.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeDelimited(w io.Writer, result Result, delim rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = delim
+
+	header := []string{
+		"family", "address", "bitmask", "netmask", "wildcard",
+		"network", "broadcast", "hostmin", "hostmax", "hosts", "computed_at",
+	}
+	row := []string{
+		result.Family, result.Address, fmt.Sprintf("%d", result.Bitmask), result.Netmask, result.Wildcard,
+		result.Network, result.Broadcast, result.Hostmin, result.Hostmax, result.Hosts, result.ComputedAt,
+	}
+
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.Write(row); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -4,7 +4,8 @@ import (
 	"flag"
 	"fmt"
 	"log"
-	"net"
+	"math/big"
+	"net/netip"
 	"os"
 	"strconv"
 	"strings"
@@ -12,8 +13,10 @@ import (
 )
 
 const (
-	cliHelp = `Network Calculator v2.2
-Usage: %[1]s <IP/CIDR>
+	cliHelp = `Network Calculator v2.4
+Usage: %[1]s [-4|-6] [-format text|json|yaml|csv|tsv] [-pretty] [-quiet] [-o file] <IP/CIDR>
+       %[1]s -batch [-i file] [-workers N] [-format text|json|csv|tsv]
+       %[1]s <subnet|host|subnets|split|join|aggregate|contains|overlaps|ptr> ...   (see -h after the subcommand)
 
 Output:
   Address     - Input IP address with network mask
@@ -28,20 +31,75 @@ Output:
 
 Example:
   %[1]s 192.168.1.0/24
+  %[1]s -format json 2001:db8::/32
 `
 )
 
+// ResultItem is a single name/value row of Result, in the order the text
+// formatter prints them.
 type ResultItem struct {
 	Name  string
 	Value string
 }
 
+// Result is the machine-readable form of a calculate() call. Hosts is a
+// string rather than a number so an IPv6 /0 count, which overflows every Go
+// integer type, survives JSON/YAML round-tripping.
+type Result struct {
+	Family     string `json:"family" yaml:"family"`
+	Address    string `json:"address" yaml:"address"`
+	Bitmask    int    `json:"bitmask" yaml:"bitmask"`
+	Netmask    string `json:"netmask" yaml:"netmask"`
+	Wildcard   string `json:"wildcard" yaml:"wildcard"`
+	Network    string `json:"network" yaml:"network"`
+	Broadcast  string `json:"broadcast" yaml:"broadcast"`
+	Hostmin    string `json:"hostmin" yaml:"hostmin"`
+	Hostmax    string `json:"hostmax" yaml:"hostmax"`
+	Hosts      string `json:"hosts" yaml:"hosts"`
+	ComputedAt string `json:"computed_at" yaml:"computed_at"`
+}
+
+// items returns Result as the ordered rows the text formatter prints.
+// Family and ComputedAt are metadata for machine formats and are left out of
+// the human-readable table to keep it unchanged from earlier versions.
+func (r Result) items() []ResultItem {
+	return []ResultItem{
+		{"Address", r.Address},
+		{"Bitmask", strconv.Itoa(r.Bitmask)},
+		{"Netmask", r.Netmask},
+		{"Wildcard", r.Wildcard},
+		{"Network", r.Network},
+		{"Broadcast", r.Broadcast},
+		{"Hostmin", r.Hostmin},
+		{"Hostmax", r.Hostmax},
+		{"Hosts", r.Hosts},
+	}
+}
+
+var (
+	force4 = flag.Bool("4", false, "require the input to be an IPv4 prefix")
+	force6 = flag.Bool("6", false, "require the input to be an IPv6 prefix")
+
+	format  = flag.String("format", "text", "output format: text, json, yaml, csv, tsv")
+	pretty  = flag.Bool("pretty", false, "indent json output")
+	quiet   = flag.Bool("quiet", false, "suppress the \"Execution time\" line")
+	outPath = flag.String("o", "", "write output to file instead of stdout")
+)
+
 func main() {
+	if dispatchSubnetCmd(os.Args[1:]) {
+		return
+	}
+
 	flag.Usage = func() {
 		fmt.Printf(cliHelp, os.Args[0])
 	}
 	flag.Parse()
 
+	if *batchMode {
+		os.Exit(runBatchMode())
+	}
+
 	if len(flag.Args()) != 1 {
 		flag.Usage()
 		os.Exit(1)
@@ -52,124 +110,159 @@ func main() {
 
 func runConsoleMode(cidr string) {
 	start := time.Now()
-	ipStr, bitmaskStr, err := parseCIDR(cidr)
+
+	result, err := calculate(cidr)
 	if err != nil {
 		log.Fatal("Error:", err)
 	}
+	result.ComputedAt = start.UTC().Format(time.RFC3339)
 
-	result, err := calculate(ipStr, bitmaskStr)
-	if err != nil {
-		log.Fatal("Error:", err)
+	w := os.Stdout
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			log.Fatal("Error:", err)
+		}
+		defer f.Close()
+		w = f
 	}
 
-	for _, item := range result {
-		fmt.Printf("%-12s %s\n", item.Name+":", item.Value)
+	if err := writeResult(w, result, *format, *pretty); err != nil {
+		log.Fatal("Error:", err)
 	}
 
-	elapsed := time.Since(start)
-	fmt.Printf("\nExecution time: %s\n", elapsed)
+	if *format == "text" && !*quiet {
+		elapsed := time.Since(start)
+		fmt.Printf("\nExecution time: %s\n", elapsed)
+	}
 }
 
-func parseCIDR(cidr string) (string, string, error) {
+// calculate parses cidr as either an IPv4 or IPv6 prefix and derives the
+// standard set of network properties for it. The returned Result has the
+// same shape regardless of address family.
+func calculate(cidr string) (Result, error) {
 	if !strings.Contains(cidr, "/") {
-		return "", "", fmt.Errorf("invalid CIDR format")
+		return Result{}, fmt.Errorf("invalid CIDR format")
 	}
 
-	_, _, err := net.ParseCIDR(cidr)
+	prefix, err := netip.ParsePrefix(cidr)
 	if err != nil {
-		return "", "", fmt.Errorf("invalid CIDR: %v", err)
+		return Result{}, fmt.Errorf("invalid CIDR: %v", err)
 	}
+	prefix = prefix.Masked()
 
-	parts := strings.Split(cidr, "/")
-	return parts[0], parts[1], nil
-}
+	addr := prefix.Addr()
+	is4 := addr.Is4()
 
-func calculate(ipStr, bitmaskStr string) ([]ResultItem, error) {
-	bitmask, err := strconv.Atoi(bitmaskStr)
-	if err != nil || bitmask < 0 || bitmask > 32 {
-		return nil, fmt.Errorf("invalid bitmask")
+	if *force4 && !is4 {
+		return Result{}, fmt.Errorf("-4 given but %q is not an IPv4 prefix", cidr)
 	}
-
-	_, ipNet, err := net.ParseCIDR(fmt.Sprintf("%s/%d", ipStr, bitmask))
-	if err != nil {
-		return nil, fmt.Errorf("invalid IP address")
+	if *force6 && is4 {
+		return Result{}, fmt.Errorf("-6 given but %q is not an IPv6 prefix", cidr)
 	}
 
-	if ipNet.IP.To4() == nil {
-		return nil, fmt.Errorf("IPv6 is not supported")
+	totalBits := 32
+	if !is4 {
+		totalBits = 128
 	}
+	ones := prefix.Bits()
 
-	ones, _ := ipNet.Mask.Size()
-	netmask := net.IP(ipNet.Mask).To4()
+	network := addr
+	last := lastAddress(network, ones, totalBits)
 
-	wildcard := make(net.IPMask, len(ipNet.Mask))
-	for i := range ipNet.Mask {
-		wildcard[i] = ^ipNet.Mask[i]
-	}
-	wildcardIP := net.IP(wildcard).To4()
+	netmaskBytes := maskBytes(totalBits, ones)
+	netmask, _ := netip.AddrFromSlice(netmaskBytes)
+	wildcard, _ := netip.AddrFromSlice(invertBytes(netmaskBytes))
 
-	network := ipNet.IP.To4()
+	var hostmin, hostmax netip.Addr
+	switch {
+	case ones == totalBits:
+		// /32 or /128: a single host.
+		hostmin, hostmax = network, network
+	case ones == totalBits-1:
+		// /31 or /127: point-to-point link, both addresses are usable
+		// (RFC 3021 for IPv4, RFC 6164 for IPv6).
+		hostmin, hostmax = network, last
+	case is4:
+		hostmin = addOffset(network, 1)
+		hostmax = addOffset(last, -1)
+	default:
+		// IPv6 has no reserved broadcast address (RFC 4291), so every
+		// address in the prefix, including the all-zero host, is usable.
+		hostmin, hostmax = network, last
+	}
 
-	broadcast := make(net.IP, len(network))
-	copy(broadcast, network)
-	for i := 0; i < 4; i++ {
-		broadcast[i] |= ^ipNet.Mask[i]
+	family := "IPv4"
+	if !is4 {
+		family = "IPv6"
 	}
 
-	var hostmin, hostmax net.IP
-	switch {
-	case ones == 32:
-		hostmin = network
-		hostmax = network
-	case ones == 31:
-		hostmin = network
-		hostmax = broadcast
-	default:
-		hostmin = make(net.IP, len(network))
-		copy(hostmin, network)
-		incrementIP(hostmin)
+	return Result{
+		Family:    family,
+		Address:   prefix.Addr().String(),
+		Bitmask:   ones,
+		Netmask:   netmask.String(),
+		Wildcard:  wildcard.String(),
+		Network:   network.String(),
+		Broadcast: last.String(),
+		Hostmin:   hostmin.String(),
+		Hostmax:   hostmax.String(),
+		Hosts:     totalHosts(is4, ones, totalBits).String(),
+	}, nil
+}
 
-		hostmax = make(net.IP, len(broadcast))
-		copy(hostmax, broadcast)
-		decrementIP(hostmax)
+// lastAddress returns the highest address in the prefix rooted at network
+// (the IPv6 equivalent of a broadcast address).
+func lastAddress(network netip.Addr, ones, totalBits int) netip.Addr {
+	b := network.AsSlice()
+	hostBits := totalBits - ones
+	for i := totalBits - 1; i >= totalBits-hostBits; i-- {
+		b[i/8] |= 1 << uint(7-i%8)
 	}
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
 
-	totalHosts := calculateTotalHosts(ones)
+// addOffset returns the address delta positions after addr, generalizing the
+// single-step increment/decrement used for IPv4 hostmin/hostmax to arbitrary
+// address lengths via big.Int.
+func addOffset(addr netip.Addr, delta int64) netip.Addr {
+	n := new(big.Int).SetBytes(addr.AsSlice())
+	n.Add(n, big.NewInt(delta))
 
-	return []ResultItem{
-		{"Address", ipStr},
-		{"Bitmask", fmt.Sprintf("%d", ones)},
-		{"Netmask", netmask.String()},
-		{"Wildcard", wildcardIP.String()},
-		{"Network", network.String()},
-		{"Broadcast", broadcast.String()},
-		{"Hostmin", hostmin.String()},
-		{"Hostmax", hostmax.String()},
-		{"Hosts", fmt.Sprintf("%d", totalHosts)},
-	}, nil
+	b := n.Bytes()
+	buf := make([]byte, addr.BitLen()/8)
+	copy(buf[len(buf)-len(b):], b)
+
+	out, _ := netip.AddrFromSlice(buf)
+	return out
 }
 
-func incrementIP(ip net.IP) {
-	for i := len(ip) - 1; i >= 0; i-- {
-		ip[i]++
-		if ip[i] != 0 {
-			break
-		}
+// maskBytes builds a totalBits-wide netmask with the top ones bits set.
+func maskBytes(totalBits, ones int) []byte {
+	buf := make([]byte, totalBits/8)
+	for i := 0; i < ones; i++ {
+		buf[i/8] |= 1 << uint(7-i%8)
 	}
+	return buf
 }
 
-func decrementIP(ip net.IP) {
-	for i := len(ip) - 1; i >= 0; i-- {
-		ip[i]--
-		if ip[i] != 255 {
-			break
-		}
+func invertBytes(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i, v := range b {
+		out[i] = ^v
 	}
+	return out
 }
 
-func calculateTotalHosts(bitmask int) uint64 {
-	if bitmask >= 31 {
-		return uint64(32 - bitmask + 1)
+// totalHosts reports the number of usable host addresses in a prefix as a
+// big.Int, since an IPv6 /8 overflows a uint64.
+func totalHosts(is4 bool, ones, totalBits int) *big.Int {
+	hostBits := totalBits - ones
+	count := new(big.Int).Lsh(big.NewInt(1), uint(hostBits))
+
+	if is4 && ones < totalBits-1 {
+		count.Sub(count, big.NewInt(2))
 	}
-	return uint64(1<<(32-bitmask)) - 2
-}
\ No newline at end of file
+	return count
+}
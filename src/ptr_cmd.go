@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"os"
+	"strings"
+)
+
+const ptrCmdHelp = `Usage:
+  %[1]s ptr [-zone domain] [-max-hosts N] [-force] <cidr>
+
+Enumerates every address in <cidr> and prints its in-addr.arpa (IPv4) or
+ip6.arpa (IPv6) name, one per line. With -zone, each line is instead a BIND
+PTR record pointing at a generated hostname under that zone.
+`
+
+func runPtrCmd(args []string) {
+	fs := flag.NewFlagSet("ptr", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprintf(os.Stderr, ptrCmdHelp, os.Args[0]) }
+	zone := fs.String("zone", "", "emit a BIND PTR zone stub using this forward zone for generated hostnames (trailing dot optional)")
+	maxHosts := fs.Int64("max-hosts", 65536, "refuse to enumerate a prefix with more addresses than this")
+	force := fs.Bool("force", false, "enumerate a prefix larger than -max-hosts anyway")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	prefix := mustParsePrefix(fs.Arg(0))
+	*zone = strings.TrimSuffix(*zone, ".")
+	is4 := prefix.Addr().Is4()
+	totalBits := 32
+	if !is4 {
+		totalBits = 128
+	}
+
+	count := new(big.Int).Lsh(big.NewInt(1), uint(totalBits-prefix.Bits()))
+	if !*force && count.Cmp(big.NewInt(*maxHosts)) > 0 {
+		fatal(fmt.Errorf("%s has %s addresses, over the -max-hosts limit of %d; pass -force to enumerate anyway", prefix, count, *maxHosts))
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+
+	one := big.NewInt(1)
+	addr := prefix.Masked().Addr()
+	for i := new(big.Int); i.Cmp(count) < 0; i.Add(i, one) {
+		if *zone != "" {
+			fmt.Fprintf(w, "%s. IN PTR %s.%s.\n", ptrName(addr), ptrHostname(addr), *zone)
+		} else {
+			fmt.Fprintln(w, ptrName(addr))
+		}
+		addr = addOffset(addr, 1)
+	}
+}
+
+// ptrName returns the in-addr.arpa or (nibble-reversed) ip6.arpa name for addr.
+func ptrName(addr netip.Addr) string {
+	if addr.Is4() {
+		o := addr.As4()
+		return fmt.Sprintf("%d.%d.%d.%d.in-addr.arpa", o[3], o[2], o[1], o[0])
+	}
+
+	b := addr.As16()
+	nibbles := make([]string, 0, 32)
+	for i := len(b) - 1; i >= 0; i-- {
+		nibbles = append(nibbles, fmt.Sprintf("%x", b[i]&0x0f), fmt.Sprintf("%x", b[i]>>4))
+	}
+	return strings.Join(nibbles, ".") + ".ip6.arpa"
+}
+
+// ptrHostname generates a forward hostname label for addr to pair with
+// -zone in the generated PTR record.
+func ptrHostname(addr netip.Addr) string {
+	if addr.Is4() {
+		o := addr.As4()
+		return fmt.Sprintf("host-%d-%d-%d-%d", o[0], o[1], o[2], o[3])
+	}
+	return "host-" + strings.ReplaceAll(addr.StringExpanded(), ":", "-")
+}
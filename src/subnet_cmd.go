@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+
+	"netcalc/pkg/cidrmath"
+)
+
+const subnetCmdHelp = `Usage:
+  %[1]s subnet <prefix> <newBits> <netNum>   Print the netNum-th /newBits-longer child subnet
+  %[1]s host <prefix> <hostNum>              Print the hostNum-th address in prefix (negative counts from the end)
+  %[1]s subnets <prefix> <newBits>...        Allocate sequential, non-overlapping child subnets
+  %[1]s split <prefix> <n>                   Divide prefix into n equal-size subnets
+  %[1]s join <prefix>...                     Collapse adjacent/contained prefixes into the smallest covering set
+  %[1]s aggregate [prefix]...                Like join, but reads stdin when no prefixes are given; output grouped by family
+  %[1]s contains <prefix> <ip-or-prefix>     Exit 0 if prefix contains the second argument, 1 otherwise
+  %[1]s overlaps <a> <b>                     Exit 0 if the two prefixes overlap, 1 otherwise
+  %[1]s ptr [-zone domain] <cidr>            Print in-addr.arpa/ip6.arpa names (or PTR records) for every address in cidr
+`
+
+// dispatchSubnetCmd handles the subnet, host, subnets, split, join,
+// aggregate, contains, overlaps and ptr subcommands. It reports whether args
+// named one of them; if not, the caller falls back to single-prefix console
+// mode.
+func dispatchSubnetCmd(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+
+	switch args[0] {
+	case "subnet":
+		runSubnetCmd(args[1:])
+	case "host":
+		runHostCmd(args[1:])
+	case "subnets":
+		runSubnetsCmd(args[1:])
+	case "split":
+		runSplitCmd(args[1:])
+	case "join":
+		runJoinCmd(args[1:])
+	case "aggregate":
+		runAggregateCmd(args[1:])
+	case "contains":
+		runContainsCmd(args[1:])
+	case "overlaps":
+		runOverlapsCmd(args[1:])
+	case "ptr":
+		runPtrCmd(args[1:])
+	default:
+		return false
+	}
+	return true
+}
+
+func runSubnetCmd(args []string) {
+	if len(args) != 3 {
+		fatalSubnetUsage()
+	}
+
+	prefix := mustParsePrefix(args[0])
+	newBits := mustAtoi(args[1])
+	netNum := mustAtoi(args[2])
+
+	result, err := cidrmath.Subnet(prefix, newBits, netNum)
+	if err != nil {
+		fatal(err)
+	}
+	fmt.Println(result)
+}
+
+func runHostCmd(args []string) {
+	if len(args) != 2 {
+		fatalSubnetUsage()
+	}
+
+	prefix := mustParsePrefix(args[0])
+	hostNum, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		fatal(fmt.Errorf("invalid hostNum %q: %v", args[1], err))
+	}
+
+	addr, err := cidrmath.Host(prefix, hostNum)
+	if err != nil {
+		fatal(err)
+	}
+	fmt.Println(addr)
+}
+
+func runSubnetsCmd(args []string) {
+	if len(args) < 2 {
+		fatalSubnetUsage()
+	}
+
+	prefix := mustParsePrefix(args[0])
+	newBits := make([]int, len(args)-1)
+	for i, a := range args[1:] {
+		newBits[i] = mustAtoi(a)
+	}
+
+	results, err := cidrmath.Subnets(prefix, newBits...)
+	if err != nil {
+		fatal(err)
+	}
+	for _, p := range results {
+		fmt.Println(p)
+	}
+}
+
+func runSplitCmd(args []string) {
+	if len(args) != 2 {
+		fatalSubnetUsage()
+	}
+
+	prefix := mustParsePrefix(args[0])
+	n := mustAtoi(args[1])
+
+	results, err := cidrmath.Split(prefix, n)
+	if err != nil {
+		fatal(err)
+	}
+	for _, p := range results {
+		fmt.Println(p)
+	}
+}
+
+func runJoinCmd(args []string) {
+	if len(args) < 1 {
+		fatalSubnetUsage()
+	}
+
+	prefixes := make([]netip.Prefix, len(args))
+	for i, a := range args {
+		prefixes[i] = mustParsePrefix(a)
+	}
+
+	results, err := cidrmath.Join(prefixes...)
+	if err != nil {
+		fatal(err)
+	}
+	for _, p := range results {
+		fmt.Println(p)
+	}
+}
+
+func mustParsePrefix(s string) netip.Prefix {
+	if !strings.Contains(s, "/") {
+		fatal(fmt.Errorf("invalid prefix %q: missing /bits", s))
+	}
+	p, err := netip.ParsePrefix(s)
+	if err != nil {
+		fatal(fmt.Errorf("invalid prefix %q: %v", s, err))
+	}
+	return p.Masked()
+}
+
+func mustAtoi(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		fatal(fmt.Errorf("invalid integer %q: %v", s, err))
+	}
+	return n
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	os.Exit(1)
+}
+
+func fatalSubnetUsage() {
+	fmt.Fprintf(os.Stderr, subnetCmdHelp, os.Args[0])
+	os.Exit(1)
+}